@@ -0,0 +1,79 @@
+// Package authn resolves externally-issued auth tokens to a canonical
+// identity via a chain of pluggable federated identity connectors (OIDC,
+// GitHub, ...), modeled on dex's connector configs. It's consumed by
+// internal/session to map an incoming AuthTokenId to a UserId and
+// scope-mapped groups before a session is created.
+package authn
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Identity is what a Connector resolves a raw token to.
+type Identity struct {
+	// Subject is the connector's stable, canonical identifier for the
+	// caller (e.g. an OIDC "sub" claim or a GitHub user id).
+	Subject string
+	// Email is the caller's email address, if the connector has one.
+	Email string
+	// Groups are the scope-mapped groups the caller belongs to, as
+	// translated by the connector (e.g. OIDC groups claim values, or
+	// GitHub "org/team" slugs).
+	Groups []string
+	// ExpiresAt is when the upstream IdP considers the resolved identity
+	// no longer valid. A Session created from this Identity should not
+	// outlive it.
+	ExpiresAt time.Time
+}
+
+// Connector resolves a raw external auth token to an Identity.
+type Connector interface {
+	// Id is the connector's configured identifier, recorded on a Session
+	// for audit purposes alongside the external Subject it resolved.
+	Id() string
+	// Exchange resolves rawToken to an Identity. It returns an error if
+	// rawToken is not one this connector knows how to validate.
+	Exchange(ctx context.Context, rawToken string) (*Identity, error)
+}
+
+// Chain resolves a raw token by trying each of its connectors in order
+// and returning the first successful Identity.
+type Chain struct {
+	connectors []Connector
+}
+
+// NewChain creates a Chain that tries connectors in the given order.
+func NewChain(connectors ...Connector) *Chain {
+	return &Chain{connectors: connectors}
+}
+
+// Resolve tries each connector in the chain in order and returns the id of
+// the connector that resolved rawToken along with the resulting Identity.
+func (c *Chain) Resolve(ctx context.Context, rawToken string) (connectorId string, identity *Identity, err error) {
+	if c == nil || len(c.connectors) == 0 {
+		return "", nil, fmt.Errorf("authn: no connectors configured")
+	}
+	var errs []error
+	for _, conn := range c.connectors {
+		id, err := conn.Exchange(ctx, rawToken)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", conn.Id(), err))
+			continue
+		}
+		return conn.Id(), id, nil
+	}
+	return "", nil, fmt.Errorf("authn: no connector could resolve token: %w", joinErrs(errs))
+}
+
+func joinErrs(errs []error) error {
+	if len(errs) == 0 {
+		return fmt.Errorf("no connectors tried")
+	}
+	msg := errs[0].Error()
+	for _, e := range errs[1:] {
+		msg += "; " + e.Error()
+	}
+	return fmt.Errorf("%s", msg)
+}
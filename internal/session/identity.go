@@ -0,0 +1,20 @@
+package session
+
+import "context"
+
+// UserMapper maps an externally-resolved identity to the canonical
+// Boundary UserId that should own a session, e.g. by looking up the
+// iam_user linked to connectorId+subject via an auth_account. A
+// connector's Identity.Subject (an OIDC "sub" claim, a GitHub numeric id,
+// ...) is never itself a valid UserId, so New requires a UserMapper
+// whenever WithConnectorChain is used.
+type UserMapper interface {
+	MapToUserId(ctx context.Context, connectorId, subject string) (string, error)
+}
+
+// GroupApplier applies the scope-mapped groups a connector resolved for an
+// identity, e.g. by translating them into Boundary managed group
+// membership or role grants for userId.
+type GroupApplier interface {
+	ApplyGroups(ctx context.Context, userId, connectorId string, groups []string) error
+}
@@ -0,0 +1,342 @@
+package session
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	mathrand "math/rand"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/boundary/internal/db"
+	"github.com/hashicorp/boundary/internal/db/timestamp"
+	wrapping "github.com/hashicorp/go-kms-wrapping"
+	"github.com/hashicorp/go-kms-wrapping/structwrapping"
+	uuid "github.com/hashicorp/go-uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultSessionSigningKeyTableName = "session_signing_key"
+
+	// defaultRotationInterval is how often the Rotator mints a new active
+	// signing key absent an explicit WithRotationInterval option.
+	defaultRotationInterval = 24 * time.Hour
+
+	// defaultRetentionAfter is how long a retired key stays verifiable
+	// before the Rotator evicts it. It must be at least as long as the
+	// longest-lived session cert so in-flight sessions can still validate.
+	defaultRetentionAfter = 7 * 24 * time.Hour
+
+	// rotationJitter bounds the random offset added to each tick so that
+	// multiple controllers in an HA deployment don't all rotate in lockstep.
+	rotationJitter = 5 * time.Minute
+)
+
+// sessionSigningKey is the database row backing a single entry in a
+// KeyRepo's ring.
+type sessionSigningKey struct {
+	// KeyId uniquely identifies this signing key; it's the kid returned by
+	// Signer and accepted by VerifierFor. It is independent of
+	// Session.KeyId, which names the kms wrapping key, not a signing key.
+	KeyId string `json:"key_id,omitempty" gorm:"primary_key"`
+	// CtPrivateKey is the wrapper-encrypted Ed25519 private key seed.
+	CtPrivateKey []byte `json:"ct_private_key,omitempty" gorm:"column:private_key;default:null" wrapping:"ct,private_key"`
+	// PrivateKey is the plaintext Ed25519 private key seed. Never stored.
+	PrivateKey []byte `json:"private_key,omitempty" gorm:"-" wrapping:"pt,private_key"`
+	// CreateTime is when this key was minted.
+	CreateTime *timestamp.Timestamp `json:"create_time,omitempty" gorm:"default:current_timestamp"`
+	// RetiredTime is set once this key is demoted from active. A nil
+	// RetiredTime means the key is the current active signer.
+	RetiredTime *timestamp.Timestamp `json:"retired_time,omitempty" gorm:"default:null"`
+	// ExpirationTime is when the key stops being accepted for verification
+	// and becomes eligible for eviction.
+	ExpirationTime *timestamp.Timestamp `json:"expiration_time,omitempty" gorm:"default:null"`
+
+	tableName string `gorm:"-"`
+}
+
+func (k *sessionSigningKey) TableName() string {
+	if k.tableName != "" {
+		return k.tableName
+	}
+	return defaultSessionSigningKeyTableName
+}
+
+func (k *sessionSigningKey) SetTableName(n string) {
+	k.tableName = n
+}
+
+func (k *sessionSigningKey) encrypt(ctx context.Context, cipher wrapping.Wrapper) error {
+	if err := structwrapping.WrapStruct(ctx, cipher, k, nil); err != nil {
+		return fmt.Errorf("error encrypting session signing key: %w", err)
+	}
+	return nil
+}
+
+func (k *sessionSigningKey) decrypt(ctx context.Context, cipher wrapping.Wrapper) error {
+	if err := structwrapping.UnwrapStruct(ctx, cipher, k, nil); err != nil {
+		return fmt.Errorf("error decrypting session signing key: %w", err)
+	}
+	return nil
+}
+
+// isRetired reports whether k has been demoted from active.
+func (k *sessionSigningKey) isRetired() bool {
+	return k.RetiredTime.GetTimestamp().AsTime().Unix() > 0
+}
+
+// KeyRepo owns the ring of Ed25519 keys used to sign and verify session
+// certs (see newCert). It keeps one active signing key plus a bounded
+// number of retired-but-still-verifiable keys so that compromising the KMS
+// wrapper at time T does not retroactively compromise certs issued before
+// the active key at T was generated.
+type KeyRepo struct {
+	reader  db.Reader
+	writer  db.Writer
+	wrapper wrapping.Wrapper
+
+	retentionAfter time.Duration
+
+	mu     sync.RWMutex
+	active *sessionSigningKey
+	ring   map[string]*sessionSigningKey
+}
+
+// NewKeyRepo creates a KeyRepo and loads its ring from the database,
+// minting an initial active key if none exists yet.
+func NewKeyRepo(ctx context.Context, r db.Reader, w db.Writer, wrapper wrapping.Wrapper, opt ...Option) (*KeyRepo, error) {
+	const op = "session.NewKeyRepo"
+	if r == nil {
+		return nil, fmt.Errorf("%s: missing reader: %w", op, db.ErrInvalidParameter)
+	}
+	if w == nil {
+		return nil, fmt.Errorf("%s: missing writer: %w", op, db.ErrInvalidParameter)
+	}
+	if wrapper == nil {
+		return nil, fmt.Errorf("%s: missing wrapper: %w", op, db.ErrInvalidParameter)
+	}
+	opts := GetOpts(opt...)
+	retentionAfter := opts.withRetentionAfter
+	if retentionAfter == 0 {
+		retentionAfter = defaultRetentionAfter
+	}
+	kr := &KeyRepo{
+		reader:         r,
+		writer:         w,
+		wrapper:        wrapper,
+		retentionAfter: retentionAfter,
+		ring:           make(map[string]*sessionSigningKey),
+	}
+	if err := kr.reload(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if kr.active == nil {
+		if err := kr.rotate(ctx); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	return kr, nil
+}
+
+// Signer returns the kid and private key that should be used to sign a new
+// session cert.
+func (kr *KeyRepo) Signer() (kid string, key ed25519.PrivateKey, err error) {
+	const op = "session.(KeyRepo).Signer"
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+	if kr.active == nil {
+		return "", nil, fmt.Errorf("%s: no active signing key: %w", op, db.ErrRecordNotFound)
+	}
+	return kr.active.KeyId, ed25519.NewKeyFromSeed(kr.active.PrivateKey), nil
+}
+
+// VerifierFor returns the public key for kid, whether kid is the current
+// active key or one of the retired-but-still-verifiable keys.
+func (kr *KeyRepo) VerifierFor(kid string) (ed25519.PublicKey, error) {
+	const op = "session.(KeyRepo).VerifierFor"
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+	k, ok := kr.ring[kid]
+	if !ok {
+		return nil, fmt.Errorf("%s: unknown key id %q: %w", op, kid, db.ErrRecordNotFound)
+	}
+	if k.isRetired() && time.Now().After(k.ExpirationTime.GetTimestamp().AsTime()) {
+		return nil, fmt.Errorf("%s: key id %q has expired: %w", op, kid, db.ErrRecordNotFound)
+	}
+	priv := ed25519.NewKeyFromSeed(k.PrivateKey)
+	pub, ok := priv.Public().(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: key %q is not ed25519: %w", op, kid, db.ErrInvalidParameter)
+	}
+	return pub, nil
+}
+
+// reload reads every row out of session_signing_key and rebuilds the
+// in-memory ring, decrypting each key's material along the way.
+func (kr *KeyRepo) reload(ctx context.Context) error {
+	const op = "session.(KeyRepo).reload"
+	var rows []*sessionSigningKey
+	if err := kr.reader.SearchWhere(ctx, &rows, "1=1", nil); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+	kr.ring = make(map[string]*sessionSigningKey, len(rows))
+	kr.active = nil
+	for _, k := range rows {
+		if err := k.decrypt(ctx, kr.wrapper); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		kr.ring[k.KeyId] = k
+		if !k.isRetired() {
+			kr.active = k
+		}
+	}
+	return nil
+}
+
+// rotate mints a new active signing key, demotes the prior active key (if
+// any) to retired, persists both, and evicts any key past retentionAfter.
+func (kr *KeyRepo) rotate(ctx context.Context) error {
+	const op = "session.(KeyRepo).rotate"
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	kid, err := uuid.GenerateUUID()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	now := time.Now()
+	next := &sessionSigningKey{
+		KeyId:      kid,
+		PrivateKey: priv.Seed(),
+		CreateTime: &timestamp.Timestamp{Timestamp: &timestamppb.Timestamp{Seconds: now.Unix()}},
+		ExpirationTime: &timestamp.Timestamp{
+			Timestamp: &timestamppb.Timestamp{Seconds: now.Add(kr.retentionAfter).Unix()},
+		},
+	}
+	if err := next.encrypt(ctx, kr.wrapper); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	kr.mu.Lock()
+	prevActive := kr.active
+	kr.mu.Unlock()
+
+	toRetire := make([]*sessionSigningKey, 0, 1)
+	if prevActive != nil {
+		retired := *prevActive
+		retired.RetiredTime = &timestamp.Timestamp{Timestamp: &timestamppb.Timestamp{Seconds: now.Unix()}}
+		retired.ExpirationTime = &timestamp.Timestamp{
+			Timestamp: &timestamppb.Timestamp{Seconds: now.Add(kr.retentionAfter).Unix()},
+		}
+		if err := retired.encrypt(ctx, kr.wrapper); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		toRetire = append(toRetire, &retired)
+	}
+
+	_, err = kr.writer.DoTx(ctx, db.StdRetryCnt, db.ExpBackoff{}, func(_ db.Reader, w db.Writer) error {
+		if err := w.Create(ctx, next); err != nil {
+			return err
+		}
+		for _, r := range toRetire {
+			if _, err := w.Update(ctx, r, []string{"RetiredTime", "ExpirationTime"}, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := next.decrypt(ctx, kr.wrapper); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+	kr.ring[next.KeyId] = next
+	kr.active = next
+	for _, r := range toRetire {
+		kr.ring[r.KeyId] = r
+	}
+	kr.evict(now)
+	return nil
+}
+
+// evict removes every retired key whose ExpirationTime has passed. Callers
+// must hold kr.mu.
+func (kr *KeyRepo) evict(now time.Time) {
+	for kid, k := range kr.ring {
+		if k.isRetired() && now.After(k.ExpirationTime.GetTimestamp().AsTime()) {
+			delete(kr.ring, kid)
+		}
+	}
+}
+
+// Rotator periodically rotates a KeyRepo's active signing key on a
+// jittered interval, so that routine operation doesn't depend on an
+// operator remembering to rotate keys by hand.
+type Rotator struct {
+	repo     *KeyRepo
+	interval time.Duration
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+// NewRotator creates a Rotator for repo. Call Start to begin the rotation
+// loop.
+func NewRotator(repo *KeyRepo, opt ...Option) (*Rotator, error) {
+	const op = "session.NewRotator"
+	if repo == nil {
+		return nil, fmt.Errorf("%s: missing key repo: %w", op, db.ErrInvalidParameter)
+	}
+	opts := GetOpts(opt...)
+	interval := opts.withRotationInterval
+	if interval == 0 {
+		interval = defaultRotationInterval
+	}
+	return &Rotator{repo: repo, interval: interval}, nil
+}
+
+// Start begins the rotation loop in a new goroutine. It returns
+// immediately; cancel ctx or call Stop to end the loop.
+func (ro *Rotator) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	ro.mu.Lock()
+	ro.cancel = cancel
+	ro.mu.Unlock()
+
+	go func() {
+		for {
+			jitter := time.Duration(mathrand.Int63n(int64(rotationJitter)))
+			t := time.NewTicker(ro.interval + jitter)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return
+			case <-t.C:
+				t.Stop()
+				// The error is dropped: a single failed rotation isn't
+				// fatal since the current active key remains valid and
+				// the next tick tries again.
+				_ = ro.repo.rotate(ctx)
+			}
+		}
+	}()
+}
+
+// Stop ends the rotation loop started by Start.
+func (ro *Rotator) Stop() {
+	ro.mu.Lock()
+	defer ro.mu.Unlock()
+	if ro.cancel != nil {
+		ro.cancel()
+	}
+}
@@ -0,0 +1,74 @@
+package session
+
+import (
+	"time"
+
+	"github.com/hashicorp/boundary/internal/authn"
+)
+
+// GetOpts iterates the inbound Options and returns a struct.
+func GetOpts(opt ...Option) options {
+	opts := getDefaultOptions()
+	for _, o := range opt {
+		o(&opts)
+	}
+	return opts
+}
+
+// Option - how Options are passed as arguments.
+type Option func(*options)
+
+// options = how options are represented.
+type options struct {
+	withRetentionAfter   time.Duration
+	withRotationInterval time.Duration
+	withConnectorChain   *authn.Chain
+	withUserMapper       UserMapper
+	withGroupApplier     GroupApplier
+}
+
+func getDefaultOptions() options {
+	return options{}
+}
+
+// WithRetentionAfter specifies how long a retired KeyRepo signing key stays
+// verifiable before it's evicted from the ring.
+func WithRetentionAfter(d time.Duration) Option {
+	return func(o *options) {
+		o.withRetentionAfter = d
+	}
+}
+
+// WithRotationInterval specifies how often a Rotator mints a new active
+// KeyRepo signing key.
+func WithRotationInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.withRotationInterval = d
+	}
+}
+
+// WithConnectorChain has New resolve a ComposedOf's AuthTokenId through
+// chain to a canonical UserId and scope-mapped groups before creating the
+// session. Requires WithUserMapper to also be supplied.
+func WithConnectorChain(chain *authn.Chain) Option {
+	return func(o *options) {
+		o.withConnectorChain = chain
+	}
+}
+
+// WithUserMapper has New map a WithConnectorChain identity's external
+// Subject to a canonical Boundary UserId, rather than aliasing the
+// external subject directly into Session.UserId.
+func WithUserMapper(m UserMapper) Option {
+	return func(o *options) {
+		o.withUserMapper = m
+	}
+}
+
+// WithGroupApplier has New apply a WithConnectorChain identity's
+// scope-mapped groups once the session's UserId has been resolved.
+func WithGroupApplier(a GroupApplier) Option {
+	return func(o *options) {
+		o.withGroupApplier = a
+	}
+}
@@ -0,0 +1,305 @@
+// Package pki manages per-scope intermediate certificate authorities used
+// to issue worker mTLS leaf certs for Boundary sessions. Rather than every
+// session cert being self-signed (and so only verifiable by a Boundary
+// controller that already knows the session), leaves are signed by a CA
+// scoped to the session's ScopeId. Workers can then authenticate an
+// incoming session presentation at the TLS layer with a standard
+// crypto/tls VerifyPeerCertificate callback, and an operator can revoke or
+// rotate trust per scope instead of per session.
+package pki
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/hashicorp/boundary/internal/db"
+	"github.com/hashicorp/boundary/internal/db/timestamp"
+	wrapping "github.com/hashicorp/go-kms-wrapping"
+	"github.com/hashicorp/go-kms-wrapping/structwrapping"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const defaultSessionScopeCATableName = "session_scope_ca"
+
+// serialNumberLimit is the upper bound (exclusive) for generated certificate
+// serial numbers: 2^159, matching the common x509 convention of a
+// non-negative serial that fits in 20 bytes.
+var serialNumberLimit = new(big.Int).Lsh(big.NewInt(1), 159)
+
+// newSerialNumber returns a cryptographically random certificate serial
+// number. Unlike math/rand, it's not deterministic across process restarts
+// and won't collide across the CAs and leaves this package issues.
+func newSerialNumber() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, serialNumberLimit)
+	if err != nil {
+		return nil, fmt.Errorf("generate certificate serial number: %w", err)
+	}
+	return serial, nil
+}
+
+// caValidity is how long a scope's intermediate CA is valid for before
+// EnsureScopeCA must mint a new one. It's intentionally long relative to
+// any one session so that workers don't need to refresh pinned roots often.
+const caValidity = 365 * 24 * time.Hour
+
+// Signer is the subset of session.KeyRepo's interface pki needs to source
+// the key that signs a scope's intermediate CA. Sourcing it from a
+// rotating repo, rather than minting an independent key that lives for
+// the full caValidity period, means compromising the live KMS wrapper
+// doesn't retroactively compromise every scope CA ever issued: a scope CA
+// minted against a since-retired root key is only as exposed as that one
+// root key's own retention window.
+type Signer interface {
+	Signer() (kid string, key ed25519.PrivateKey, err error)
+}
+
+// sessionScopeCA is the database row backing a scope's intermediate CA.
+type sessionScopeCA struct {
+	ScopeId string `json:"scope_id,omitempty" gorm:"primary_key"`
+	// CtPrivateKey is the wrapper-encrypted Ed25519 private key seed for
+	// the scope's intermediate CA.
+	CtPrivateKey []byte `json:"ct_private_key,omitempty" gorm:"column:private_key;default:null" wrapping:"ct,private_key"`
+	// PrivateKey is the plaintext private key seed. Never stored.
+	PrivateKey []byte `json:"private_key,omitempty" gorm:"-" wrapping:"pt,private_key"`
+	// Certificate is the DER-encoded intermediate CA certificate.
+	Certificate []byte `json:"certificate,omitempty" gorm:"default:null"`
+	// KeyId is the kms wrapping key id used to encrypt PrivateKey.
+	KeyId string `json:"key_id,omitempty" gorm:"not_null"`
+	// RootKeyId is the Signer kid whose key signed Certificate, so a
+	// re-mint can tell whether the root it chains to is still current.
+	RootKeyId      string               `json:"root_key_id,omitempty" gorm:"default:null"`
+	CreateTime     *timestamp.Timestamp `json:"create_time,omitempty" gorm:"default:current_timestamp"`
+	ExpirationTime *timestamp.Timestamp `json:"expiration_time,omitempty" gorm:"default:null"`
+
+	tableName string `gorm:"-"`
+}
+
+func (c *sessionScopeCA) TableName() string {
+	if c.tableName != "" {
+		return c.tableName
+	}
+	return defaultSessionScopeCATableName
+}
+
+func (c *sessionScopeCA) SetTableName(n string) {
+	c.tableName = n
+}
+
+func (c *sessionScopeCA) encrypt(ctx context.Context, cipher wrapping.Wrapper) error {
+	if err := structwrapping.WrapStruct(ctx, cipher, c, nil); err != nil {
+		return fmt.Errorf("error encrypting session scope ca: %w", err)
+	}
+	c.KeyId = cipher.KeyID()
+	return nil
+}
+
+func (c *sessionScopeCA) decrypt(ctx context.Context, cipher wrapping.Wrapper) error {
+	if err := structwrapping.UnwrapStruct(ctx, cipher, c, nil); err != nil {
+		return fmt.Errorf("error decrypting session scope ca: %w", err)
+	}
+	return nil
+}
+
+// ScopeCA is a scope's intermediate certificate authority used to issue
+// worker mTLS leaf certs for sessions in that scope.
+type ScopeCA struct {
+	ScopeId string
+	Cert    *x509.Certificate
+	DER     []byte
+
+	key ed25519.PrivateKey
+}
+
+// Bundle returns the PEM-encoded CA certificate for ScopeId, suitable for
+// an admin endpoint to hand to operators who want to pin workers to this
+// scope's roots.
+func (c *ScopeCA) Bundle() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.DER})
+}
+
+// EnsureScopeCA returns the intermediate CA for scopeId, lazily creating
+// and KMS-wrapping one via wrapper if it doesn't already exist, and
+// re-minting it if the stored one has passed its ExpirationTime. root
+// supplies the rotating key that signs the scope CA (see Signer).
+func EnsureScopeCA(ctx context.Context, scopeId string, r db.Reader, w db.Writer, wrapper wrapping.Wrapper, root Signer) (*ScopeCA, error) {
+	const op = "pki.EnsureScopeCA"
+	if scopeId == "" {
+		return nil, fmt.Errorf("%s: missing scope id: %w", op, db.ErrInvalidParameter)
+	}
+	if wrapper == nil {
+		return nil, fmt.Errorf("%s: missing wrapper: %w", op, db.ErrInvalidParameter)
+	}
+	if root == nil {
+		return nil, fmt.Errorf("%s: missing root signer: %w", op, db.ErrInvalidParameter)
+	}
+
+	row := &sessionScopeCA{ScopeId: scopeId}
+	err := r.LookupByPublicId(ctx, row)
+	switch {
+	case err == nil:
+		if err := row.decrypt(ctx, wrapper); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if row.ExpirationTime.GetTimestamp().AsTime().After(time.Now()) {
+			return toScopeCA(row)
+		}
+		fresh, err := mintScopeCA(scopeId, root)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if err := fresh.encrypt(ctx, wrapper); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if _, err := w.Update(ctx, fresh, []string{"CtPrivateKey", "Certificate", "KeyId", "RootKeyId", "CreateTime", "ExpirationTime"}, nil); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if err := fresh.decrypt(ctx, wrapper); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		return toScopeCA(fresh)
+	case err != db.ErrRecordNotFound:
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	row, err = mintScopeCA(scopeId, root)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := row.encrypt(ctx, wrapper); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := w.Create(ctx, row); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := row.decrypt(ctx, wrapper); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return toScopeCA(row)
+}
+
+// mintScopeCA generates a fresh keypair for scopeId's intermediate CA and
+// issues its certificate using root's current active signing key, so the
+// CA's trust ultimately traces back to a key that KeyRepo can rotate and
+// retire independently of the CA's own caValidity lifetime.
+func mintScopeCA(scopeId string, root Signer) (*sessionScopeCA, error) {
+	rootKid, rootKey, err := root.Signer()
+	if err != nil {
+		return nil, err
+	}
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := newSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: fmt.Sprintf("boundary scope ca: %s", scopeId)},
+		SerialNumber:          serial,
+		NotBefore:             now.Add(-1 * time.Minute),
+		NotAfter:              now.Add(caValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	issuer := &x509.Certificate{
+		Subject: pkix.Name{CommonName: fmt.Sprintf("boundary root: %s", rootKid)},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, pubKey, rootKey)
+	if err != nil {
+		return nil, err
+	}
+	return &sessionScopeCA{
+		ScopeId:     scopeId,
+		PrivateKey:  privKey.Seed(),
+		Certificate: der,
+		RootKeyId:   rootKid,
+		CreateTime:  &timestamp.Timestamp{Timestamp: &timestamppb.Timestamp{Seconds: now.Unix()}},
+		ExpirationTime: &timestamp.Timestamp{
+			Timestamp: &timestamppb.Timestamp{Seconds: now.Add(caValidity).Unix()},
+		},
+	}, nil
+}
+
+func toScopeCA(row *sessionScopeCA) (*ScopeCA, error) {
+	cert, err := x509.ParseCertificate(row.Certificate)
+	if err != nil {
+		return nil, fmt.Errorf("parse scope ca certificate: %w", err)
+	}
+	return &ScopeCA{
+		ScopeId: row.ScopeId,
+		Cert:    cert,
+		DER:     row.Certificate,
+		key:     ed25519.NewKeyFromSeed(row.PrivateKey),
+	}, nil
+}
+
+// IssueLeaf issues a worker mTLS leaf cert for userId/jobId signed by
+// scopeCA, valid until exp.
+func IssueLeaf(scopeCA *ScopeCA, userId, jobId string, exp time.Time) (ed25519.PrivateKey, []byte, error) {
+	const op = "pki.IssueLeaf"
+	if scopeCA == nil {
+		return nil, nil, fmt.Errorf("%s: missing scope ca: %w", op, db.ErrInvalidParameter)
+	}
+	if userId == "" {
+		return nil, nil, fmt.Errorf("%s: missing user id: %w", op, db.ErrInvalidParameter)
+	}
+	if jobId == "" {
+		return nil, nil, fmt.Errorf("%s: missing job id: %w", op, db.ErrInvalidParameter)
+	}
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	serial, err := newSerialNumber()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	template := &x509.Certificate{
+		Subject:      pkix.Name{CommonName: userId},
+		DNSNames:     []string{jobId},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment | x509.KeyUsageKeyAgreement,
+		SerialNumber: serial,
+		NotBefore:    time.Now().Add(-1 * time.Minute),
+		NotAfter:     exp,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, scopeCA.Cert, pubKey, scopeCA.key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return privKey, der, nil
+}
+
+// VerifyChain parses leafDER and confirms it chains to scopeCA, returning
+// the parsed leaf on success. It's meant to be called from a
+// crypto/tls.Config's VerifyPeerCertificate so that workers can require
+// and verify session client certs at the TLS layer.
+func VerifyChain(leafDER []byte, scopeCA *ScopeCA) (*x509.Certificate, error) {
+	const op = "pki.VerifyChain"
+	if scopeCA == nil {
+		return nil, fmt.Errorf("%s: missing scope ca: %w", op, db.ErrInvalidParameter)
+	}
+	leaf, err := x509.ParseCertificate(leafDER)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(scopeCA.Cert)
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+	}); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return leaf, nil
+}
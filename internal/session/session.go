@@ -3,16 +3,13 @@ package session
 import (
 	"context"
 	"crypto/ed25519"
-	"crypto/rand"
-	"crypto/x509"
 	"fmt"
-	"math/big"
-	mathrand "math/rand"
 	"strings"
 	"time"
 
 	"github.com/hashicorp/boundary/internal/db"
 	"github.com/hashicorp/boundary/internal/db/timestamp"
+	"github.com/hashicorp/boundary/internal/session/pki"
 	wrapping "github.com/hashicorp/go-kms-wrapping"
 	"github.com/hashicorp/go-kms-wrapping/structwrapping"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -95,6 +92,20 @@ type Session struct {
 	// @inject_tag: `gorm:"not_null"`
 	KeyId string `json:"key_id,omitempty" gorm:"not_null"`
 
+	// ConnectorId is the id of the authn.Connector that resolved this
+	// session's AuthTokenId to a UserId, if any. Empty for sessions created
+	// without a connector chain.
+	ConnectorId string `json:"connector_id,omitempty" gorm:"default:null"`
+	// ExternalSubject is the canonical subject the connector resolved the
+	// caller to (e.g. an OIDC "sub" claim or GitHub user id), recorded
+	// alongside ConnectorId for audit purposes.
+	ExternalSubject string `json:"external_subject,omitempty" gorm:"default:null"`
+
+	// Groups are the scope-mapped groups a connector resolved for this
+	// session's caller, applied via the configured GroupApplier at create
+	// time. Read only; not persisted on the session row itself.
+	Groups []string `json:"groups,omitempty" gorm:"-"`
+
 	// States for the session which are for read only and are ignored during
 	// write operations
 	States    []*State `gorm:"-"`
@@ -108,8 +119,12 @@ func (s *Session) GetPublicId() string {
 var _ Cloneable = (*Session)(nil)
 var _ db.VetForWriter = (*Session)(nil)
 
-// New creates a new in memory session.
-func New(c ComposedOf, opt ...Option) (*Session, error) {
+// New creates a new in memory session. If opt includes WithConnectorChain,
+// c.AuthTokenId is resolved through the chain to a canonical UserId and
+// recorded for audit before the session is validated, and the session's
+// ExpirationTime is bounded by the resolved identity's expiration so that
+// it can never outlive the upstream IdP token it came from.
+func New(ctx context.Context, c ComposedOf, opt ...Option) (*Session, error) {
 	s := Session{
 		UserId:          c.UserId,
 		HostId:          c.HostId,
@@ -121,6 +136,34 @@ func New(c ComposedOf, opt ...Option) (*Session, error) {
 		ExpirationTime:  c.ExpirationTime,
 		ConnectionLimit: c.ConnectionLimit,
 	}
+	opts := GetOpts(opt...)
+	if opts.withConnectorChain != nil {
+		if opts.withUserMapper == nil {
+			return nil, fmt.Errorf("new session: connector chain configured without a user mapper: %w", db.ErrInvalidParameter)
+		}
+		connectorId, identity, err := opts.withConnectorChain.Resolve(ctx, c.AuthTokenId)
+		if err != nil {
+			return nil, fmt.Errorf("new session: resolving auth token id: %w", err)
+		}
+		userId, err := opts.withUserMapper.MapToUserId(ctx, connectorId, identity.Subject)
+		if err != nil {
+			return nil, fmt.Errorf("new session: mapping external identity to user: %w", err)
+		}
+		s.UserId = userId
+		s.ConnectorId = connectorId
+		s.ExternalSubject = identity.Subject
+		s.Groups = identity.Groups
+		if opts.withGroupApplier != nil {
+			if err := opts.withGroupApplier.ApplyGroups(ctx, userId, connectorId, identity.Groups); err != nil {
+				return nil, fmt.Errorf("new session: applying resolved groups: %w", err)
+			}
+		}
+		if !identity.ExpiresAt.IsZero() && identity.ExpiresAt.Before(s.ExpirationTime.GetTimestamp().AsTime()) {
+			s.ExpirationTime = &timestamp.Timestamp{
+				Timestamp: &timestamppb.Timestamp{Seconds: identity.ExpiresAt.Unix()},
+			}
+		}
+	}
 	if err := s.validateNewSession("new session:"); err != nil {
 		return nil, err
 	}
@@ -148,6 +191,12 @@ func (s *Session) Clone() interface{} {
 		Version:           s.Version,
 		Endpoint:          s.Endpoint,
 		ConnectionLimit:   s.ConnectionLimit,
+		ConnectorId:       s.ConnectorId,
+		ExternalSubject:   s.ExternalSubject,
+	}
+	if s.Groups != nil {
+		clone.Groups = make([]string, len(s.Groups))
+		copy(clone.Groups, s.Groups)
 	}
 	if s.TofuToken != nil {
 		clone.TofuToken = make([]byte, len(s.TofuToken))
@@ -306,9 +355,23 @@ func contains(ss []string, t string) bool {
 	return false
 }
 
-func newCert(wrapper wrapping.Wrapper, userId, jobId string, exp time.Time) (ed25519.PrivateKey, []byte, error) {
-	if wrapper == nil {
-		return nil, nil, fmt.Errorf("new session cert: missing wrapper: %w", db.ErrInvalidParameter)
+// newCert issues a worker mTLS leaf cert for userId/jobId, signed by the
+// intermediate CA for scopeId rather than self-signed. This lets workers
+// authenticate a session presentation at the TLS layer with a standard
+// crypto/tls VerifyPeerCertificate against the scope's pinned roots
+// instead of a Boundary-specific handshake, and lets an operator revoke or
+// rotate trust per scope rather than per session.
+//
+// keyRepo supplies the rotating key that signs the scope's intermediate
+// CA (see KeyRepo.Signer and pki.Signer), so that compromising the live
+// KMS wrapper doesn't retroactively compromise every scope CA issued
+// against it.
+func newCert(ctx context.Context, r db.Reader, w db.Writer, wrapper wrapping.Wrapper, keyRepo *KeyRepo, scopeId, userId, jobId string, exp time.Time) (ed25519.PrivateKey, []byte, error) {
+	if keyRepo == nil {
+		return nil, nil, fmt.Errorf("new session cert: missing key repo: %w", db.ErrInvalidParameter)
+	}
+	if scopeId == "" {
+		return nil, nil, fmt.Errorf("new session cert: missing scope id: %w", db.ErrInvalidParameter)
 	}
 	if userId == "" {
 		return nil, nil, fmt.Errorf("new session cert: missing user id: %w", db.ErrInvalidParameter)
@@ -316,25 +379,11 @@ func newCert(wrapper wrapping.Wrapper, userId, jobId string, exp time.Time) (ed2
 	if jobId == "" {
 		return nil, nil, fmt.Errorf("new session cert: missing job id: %w", db.ErrInvalidParameter)
 	}
-	pubKey, privKey, err := DeriveED25519Key(wrapper, userId, jobId)
+	scopeCA, err := pki.EnsureScopeCA(ctx, scopeId, r, w, wrapper, keyRepo)
 	if err != nil {
-		return nil, nil, fmt.Errorf("new session cert: ")
-	}
-	template := &x509.Certificate{
-		ExtKeyUsage: []x509.ExtKeyUsage{
-			x509.ExtKeyUsageServerAuth,
-			x509.ExtKeyUsageClientAuth,
-		},
-		DNSNames:              []string{jobId},
-		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment | x509.KeyUsageKeyAgreement | x509.KeyUsageCertSign,
-		SerialNumber:          big.NewInt(mathrand.Int63()),
-		NotBefore:             time.Now().Add(-1 * time.Minute),
-		NotAfter:              exp,
-		BasicConstraintsValid: true,
-		IsCA:                  true,
+		return nil, nil, fmt.Errorf("new session cert: %w", err)
 	}
-
-	certBytes, err := x509.CreateCertificate(rand.Reader, template, template, pubKey, privKey)
+	privKey, certBytes, err := pki.IssueLeaf(scopeCA, userId, jobId, exp)
 	if err != nil {
 		return nil, nil, fmt.Errorf("new session cert: %w", err)
 	}
@@ -0,0 +1,243 @@
+package session
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/boundary/internal/db"
+	"github.com/hashicorp/boundary/internal/db/timestamp"
+	uuid "github.com/hashicorp/go-uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ConnectContext restricts a SessionToken to a single connection attempt
+// against a specific target/host pairing.
+type ConnectContext struct {
+	TargetId        string `json:"target_id,omitempty"`
+	HostId          string `json:"host_id,omitempty"`
+	ConnectionCount int32  `json:"connection_count,omitempty"`
+}
+
+// AdminContext restricts a SessionToken to a single administrative verb
+// (e.g. "cancel") performed against the parent session.
+type AdminContext struct {
+	Verb string `json:"verb,omitempty"`
+}
+
+// TokenContext is a oneof describing the single operation a SessionToken
+// authorizes. Exactly one of its fields should be set.
+type TokenContext struct {
+	Connect *ConnectContext `json:"connect,omitempty"`
+	Admin   *AdminContext   `json:"admin,omitempty"`
+}
+
+// equal reports whether tc authorizes the same operation as other. Unset
+// fields on either side never match.
+func (tc TokenContext) equal(other TokenContext) bool {
+	switch {
+	case tc.Connect != nil && other.Connect != nil:
+		return *tc.Connect == *other.Connect
+	case tc.Admin != nil && other.Admin != nil:
+		return *tc.Admin == *other.Admin
+	default:
+		return false
+	}
+}
+
+// narrows reports whether tc authorizes no more than parent does: the same
+// kind of operation, restricted to an equal or smaller scope. It's used by
+// Restrict to reject a child token that would broaden, rather than narrow,
+// what its parent authorized.
+func (tc TokenContext) narrows(parent TokenContext) bool {
+	switch {
+	case parent.Connect != nil:
+		if tc.Connect == nil {
+			return false
+		}
+		if tc.Connect.TargetId != parent.Connect.TargetId || tc.Connect.HostId != parent.Connect.HostId {
+			return false
+		}
+		if parent.Connect.ConnectionCount > 0 &&
+			(tc.Connect.ConnectionCount <= 0 || tc.Connect.ConnectionCount > parent.Connect.ConnectionCount) {
+			return false
+		}
+		return true
+	case parent.Admin != nil:
+		return tc.Admin != nil && tc.Admin.Verb == parent.Admin.Verb
+	default:
+		return false
+	}
+}
+
+// SessionToken is a delegated, scope-restricted proof of authorization
+// derived from a Session. A session holder mints a SessionToken to hand a
+// single, narrow capability (e.g. "make one connection to this target") to a
+// helper process without sharing the parent session's full authority.
+type SessionToken struct {
+	// ParentId is the PublicId of the Session this token was derived from.
+	ParentId string `json:"parent_id,omitempty"`
+	// Context describes the single operation this token authorizes.
+	Context TokenContext `json:"context,omitempty"`
+	// IssuedAt is when the token was minted.
+	IssuedAt *timestamp.Timestamp `json:"issued_at,omitempty"`
+	// ExpirationTime is when the token stops being valid. It can never be
+	// later than the parent session's ExpirationTime.
+	ExpirationTime *timestamp.Timestamp `json:"expiration_time,omitempty"`
+	// Id is a random nonce that uniquely identifies this token. Verify does
+	// not track ids it has already seen, so Id alone is not a single-use /
+	// replay-prevention mechanism; a caller that needs one must track
+	// presented ids itself (e.g. keyed by Id, until ExpirationTime).
+	Id string `json:"id,omitempty"`
+	// Signature is an Ed25519 signature, produced with the private key
+	// paired with the public key embedded in the parent session's
+	// certificate, over the rest of the token's fields.
+	Signature []byte `json:"signature,omitempty"`
+}
+
+// NewToken mints a SessionToken delegated from s, authorizing only the
+// operation described by tc. privKey must be the private key paired with
+// the public key embedded in s.Certificate (see newCert, which sources it
+// from the session's scope CA via pki.IssueLeaf); exp must not be later
+// than s.ExpirationTime.
+func NewToken(s *Session, privKey ed25519.PrivateKey, tc TokenContext, exp time.Time) (*SessionToken, error) {
+	const op = "session.NewToken"
+	if s == nil {
+		return nil, fmt.Errorf("%s: missing session: %w", op, db.ErrInvalidParameter)
+	}
+	if s.PublicId == "" {
+		return nil, fmt.Errorf("%s: missing session public id: %w", op, db.ErrInvalidParameter)
+	}
+	if len(privKey) == 0 {
+		return nil, fmt.Errorf("%s: missing private key: %w", op, db.ErrInvalidParameter)
+	}
+	if tc.Connect == nil && tc.Admin == nil {
+		return nil, fmt.Errorf("%s: missing token context: %w", op, db.ErrInvalidParameter)
+	}
+	if exp.After(s.ExpirationTime.GetTimestamp().AsTime()) {
+		return nil, fmt.Errorf("%s: expiration exceeds parent session's expiration: %w", op, db.ErrInvalidParameter)
+	}
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	t := &SessionToken{
+		ParentId: s.PublicId,
+		Context:  tc,
+		Id:       id,
+		IssuedAt: &timestamp.Timestamp{
+			Timestamp: &timestamppb.Timestamp{Seconds: time.Now().Unix()},
+		},
+		ExpirationTime: &timestamp.Timestamp{
+			Timestamp: &timestamppb.Timestamp{Seconds: exp.Unix()},
+		},
+	}
+	sigBytes, err := t.signingBytes()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	t.Signature = ed25519.Sign(privKey, sigBytes)
+	return t, nil
+}
+
+// Restrict mints a further narrowed SessionToken from t, re-signed with
+// privKey. tc must be a narrowing of t's own context (same operation, equal
+// or smaller scope) and the child's expiration can be no later than t's own
+// expiration; privKey must still correspond to the parent session's
+// certificate.
+func (t *SessionToken) Restrict(privKey ed25519.PrivateKey, tc TokenContext, exp time.Time) (*SessionToken, error) {
+	const op = "session.(SessionToken).Restrict"
+	if t == nil {
+		return nil, fmt.Errorf("%s: missing token: %w", op, db.ErrInvalidParameter)
+	}
+	if !tc.narrows(t.Context) {
+		return nil, fmt.Errorf("%s: context is not a narrowing of the parent token's context: %w", op, db.ErrInvalidParameter)
+	}
+	if exp.After(t.ExpirationTime.GetTimestamp().AsTime()) {
+		return nil, fmt.Errorf("%s: expiration exceeds token's expiration: %w", op, db.ErrInvalidParameter)
+	}
+	child := &Session{
+		PublicId:       t.ParentId,
+		ExpirationTime: t.ExpirationTime,
+	}
+	return NewToken(child, privKey, tc, exp)
+}
+
+// Verify confirms that t was validly issued for s and authorizes requested.
+// It checks the Ed25519 signature against the public key embedded in
+// s.Certificate, that t has not expired, that t's expiration does not exceed
+// s's expiration, and that requested matches the token's embedded context.
+func (t *SessionToken) Verify(s *Session, requested TokenContext) error {
+	const op = "session.(SessionToken).Verify"
+	if t == nil {
+		return fmt.Errorf("%s: missing token: %w", op, db.ErrInvalidParameter)
+	}
+	if s == nil {
+		return fmt.Errorf("%s: missing session: %w", op, db.ErrInvalidParameter)
+	}
+	if t.ParentId != s.PublicId {
+		return fmt.Errorf("%s: token does not belong to session: %w", op, db.ErrInvalidParameter)
+	}
+	if len(s.Certificate) == 0 {
+		return fmt.Errorf("%s: session has no certificate: %w", op, db.ErrInvalidParameter)
+	}
+	cert, err := x509.ParseCertificate(s.Certificate)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	pubKey, ok := cert.PublicKey.(ed25519.PublicKey)
+	if !ok {
+		return fmt.Errorf("%s: session certificate is not ed25519: %w", op, db.ErrInvalidParameter)
+	}
+	sigBytes, err := t.signingBytes()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !ed25519.Verify(pubKey, sigBytes, t.Signature) {
+		return fmt.Errorf("%s: invalid signature: %w", op, db.ErrInvalidParameter)
+	}
+	if t.ExpirationTime.GetTimestamp().AsTime().After(s.ExpirationTime.GetTimestamp().AsTime()) {
+		return fmt.Errorf("%s: token outlives parent session: %w", op, db.ErrInvalidParameter)
+	}
+	if time.Now().After(t.ExpirationTime.GetTimestamp().AsTime()) {
+		return fmt.Errorf("%s: token has expired: %w", op, db.ErrInvalidParameter)
+	}
+	if !t.Context.equal(requested) {
+		return fmt.Errorf("%s: token does not authorize requested operation: %w", op, db.ErrInvalidParameter)
+	}
+	return nil
+}
+
+// Marshal serializes t for transport to, or storage by, the party a token
+// is being delegated to.
+func (t *SessionToken) Marshal() ([]byte, error) {
+	const op = "session.(SessionToken).Marshal"
+	b, err := json.Marshal(t)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return b, nil
+}
+
+// Unmarshal populates t from bytes produced by Marshal.
+func (t *SessionToken) Unmarshal(data []byte) error {
+	const op = "session.(SessionToken).Unmarshal"
+	if err := json.Unmarshal(data, t); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// signingBytes returns the canonical, deterministic encoding of every
+// SessionToken field except Signature itself.
+func (t *SessionToken) signingBytes() ([]byte, error) {
+	unsigned := *t
+	unsigned.Signature = nil
+	b, err := json.Marshal(&unsigned)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}